@@ -0,0 +1,126 @@
+// Copyright 2024 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !race
+// +build !race
+
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/hugelgupf/vmtest"
+	"github.com/hugelgupf/vmtest/qemu"
+	"github.com/hugelgupf/vmtest/testtmp"
+	uqemu "github.com/u-root/u-root/pkg/qemu"
+	"github.com/u-root/u-root/pkg/uroot"
+	"github.com/u-root/u-root/pkg/vmtest/guestimage"
+)
+
+// TestDhclientAgainstDistroServer is an alternative to TestDhclient's
+// server side: rather than a u-root VM running this repo's own
+// integration/testcmd/pxeserver, the DHCP server is a real distro
+// (alpine-3.19) running dnsmasq, so cmds/core/dhclient gets exercised
+// against an independent, widely-deployed DHCP server implementation
+// instead of only our own test double.
+//
+// The two guests share an isolated uqemu.Topology segment rather than
+// the qemu.Network TestDhclient itself uses, since that type's
+// definition isn't part of this tree and can't be safely extended to
+// add a distro-backed server to.
+func TestDhclientAgainstDistroServer(t *testing.T) {
+	if os.Getenv("VMTEST_GUESTIMAGE") == "" {
+		t.Skip("set VMTEST_GUESTIMAGE=1 to run guest image boot tests")
+	}
+	if _, err := exec.LookPath("qemu-system-x86_64"); err != nil {
+		t.Skip("qemu-system-x86_64 not found")
+	}
+	if _, err := exec.LookPath("genisoimage"); err != nil {
+		t.Skip("genisoimage not found")
+	}
+	vmtest.SkipIfNotArch(t, qemu.ArchAMD64)
+
+	distros, err := guestimage.LoadManifest("../testdata/distros.json")
+	if err != nil {
+		t.Fatalf("LoadManifest: %v", err)
+	}
+	d, err := guestimage.Find(distros, "alpine-3.19")
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	imagePath, err := guestimage.Fetch(d)
+	if err != nil {
+		t.Skipf("could not fetch guest image %s: %v", d.Name, err)
+	}
+
+	// eth0 keeps the default SSH NIC (unused here, but Boot always adds
+	// it); eth1 is brought up with a static address and dnsmasq leases
+	// out 192.168.60.10-192.168.60.100 from that /24.
+	seedISO, err := guestimage.WriteSeedISOWithRunCmds(t.TempDir(), d, []string{"dnsmasq"}, []string{
+		"ip addr add 192.168.60.1/24 dev eth1 && ip link set eth1 up",
+		"dnsmasq --interface=eth1 --bind-interfaces --except-interface=lo --dhcp-range=192.168.60.10,192.168.60.100,1h",
+	})
+	if err != nil {
+		t.Fatalf("WriteSeedISOWithRunCmds: %v", err)
+	}
+
+	sshFwd, err := uqemu.NewHostFwd("ssh", 22)
+	if err != nil {
+		t.Fatalf("NewHostFwd: %v", err)
+	}
+
+	top := uqemu.NewTopology()
+	seg := top.NewSegment("dhcp")
+	serverNIC := seg.NIC("52:54:00:00:00:01").Args("amd64", "server")
+	clientNIC := seg.NIC("52:54:00:00:00:02").Args("amd64", "client")
+
+	proc, err := guestimage.Boot(d, imagePath, seedISO, guestimage.BootOptions{
+		SSHHostPort: sshFwd.HostPort(),
+		ExtraArgs:   serverNIC,
+	})
+	if err != nil {
+		t.Fatalf("Boot: %v", err)
+	}
+	defer func() {
+		_ = proc.Kill()
+		_ = proc.Wait()
+	}()
+	// cloud-init's runcmd stage (which starts dnsmasq) runs well after
+	// boot; there's no readiness signal to poll on here since, unlike
+	// the other guestimage tests, this one never dials the server over
+	// SSH, so give it a generous fixed head start instead.
+	time.Sleep(30 * time.Second)
+
+	vm := vmtest.StartVMAndRunCmds(t, []string{
+		"ip link set eth0 up",
+		"dhclient -ipv6=false -v eth0",
+		"ip a",
+		"shutdown -h",
+	},
+		vmtest.WithMergedInitramfs(uroot.Opts{
+			Commands: uroot.BusyBoxCmds(
+				"github.com/u-root/u-root/cmds/core/ip",
+				"github.com/u-root/u-root/cmds/core/init",
+				"github.com/u-root/u-root/cmds/core/dhclient",
+				"github.com/u-root/u-root/cmds/core/shutdown",
+			),
+		}),
+		vmtest.WithQEMUFn(
+			qemu.WithVMTimeout(time.Minute),
+			qemu.ArbitraryArgs(clientNIC...),
+		),
+		vmtest.WithSharedDir(testtmp.TempDir(t)),
+	)
+	defer func() {
+		_ = vm.Kill()
+		_ = vm.Wait()
+	}()
+
+	if _, err := vm.Console.ExpectString("inet 192.168.60."); err != nil {
+		t.Fatalf("client did not get a lease from the distro dnsmasq server: %v", err)
+	}
+}
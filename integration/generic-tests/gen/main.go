@@ -0,0 +1,129 @@
+// Copyright 2024 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Command gen reads matrix.hujson and emits
+// matrix_generated_test.go, one top-level Test function per
+// kernel/arch/kexec-mode combination. It's a plain Go program, not a
+// text/template, and the generated code is run through go/format before
+// it's written out. It is not type-checked.
+//
+// Run via `go generate ./...` from the generic-tests package, which
+// carries the //go:generate directive that invokes this command.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"strings"
+)
+
+type mode struct {
+	Name       string `json:"name"`
+	Flag       string `json:"flag"`
+	DTB        bool   `json:"dtb"`
+	Compressed bool   `json:"compressed"`
+}
+
+type manifest struct {
+	Kernels []string `json:"kernels"`
+	Archs   []string `json:"archs"`
+	Modes   []mode   `json:"modes"`
+}
+
+type combination struct {
+	Kernel string
+	Arch   string
+	Mode   mode
+}
+
+func main() {
+	manifestPath := flag.String("manifest", "matrix.hujson", "path to the matrix manifest")
+	outPath := flag.String("out", "matrix_generated_test.go", "path to write the generated test file to")
+	check := flag.Bool("check", false, "check that out is up to date instead of writing it")
+	flag.Parse()
+
+	if err := run(*manifestPath, *outPath, *check); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(manifestPath, outPath string, check bool) error {
+	b, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return fmt.Errorf("reading manifest: %w", err)
+	}
+	var m manifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return fmt.Errorf("parsing manifest %s: %w", manifestPath, err)
+	}
+
+	src, err := render(combinations(m))
+	if err != nil {
+		return fmt.Errorf("rendering generated test file: %w", err)
+	}
+
+	if check {
+		existing, err := os.ReadFile(outPath)
+		if err != nil {
+			return fmt.Errorf("reading existing %s: %w", outPath, err)
+		}
+		if string(existing) != string(src) {
+			return fmt.Errorf("%s is out of date relative to %s; run `go generate ./...`", outPath, manifestPath)
+		}
+		return nil
+	}
+
+	return os.WriteFile(outPath, src, 0o644)
+}
+
+// combinations expands a manifest into the list of test combinations,
+// dropping combinations that don't make sense (a custom DTB is only
+// meaningful on arm64).
+func combinations(m manifest) []combination {
+	var combos []combination
+	for _, kernel := range m.Kernels {
+		for _, arch := range m.Archs {
+			for _, mo := range m.Modes {
+				if mo.DTB && arch != "arm64" {
+					continue
+				}
+				combos = append(combos, combination{Kernel: kernel, Arch: arch, Mode: mo})
+			}
+		}
+	}
+	return combos
+}
+
+func testName(c combination) string {
+	kernel := strings.ReplaceAll(c.Kernel, ".", "_")
+	arch := strings.ToUpper(c.Arch[:1]) + c.Arch[1:]
+	return fmt.Sprintf("TestKexec_%s_%s_%s", arch, c.Mode.Name, kernel)
+}
+
+func render(combos []combination) ([]byte, error) {
+	var sb strings.Builder
+	sb.WriteString("// Code generated by gen from matrix.hujson; DO NOT EDIT.\n\n")
+	sb.WriteString("//go:build !race\n// +build !race\n\n")
+	sb.WriteString("package integration\n\n")
+	sb.WriteString("import \"testing\"\n\n")
+
+	for _, c := range combos {
+		fmt.Fprintf(&sb, "// %s runs the %s kexec flow on kernel %s/%s.\n", testName(c), c.Mode.Name, c.Kernel, c.Arch)
+		fmt.Fprintf(&sb, "func %s(t *testing.T) {\n", testName(c))
+		fmt.Fprintf(&sb, "\trunMatrixCombination(t, matrixCombination{\n")
+		fmt.Fprintf(&sb, "\t\tKernel:     %q,\n", c.Kernel)
+		fmt.Fprintf(&sb, "\t\tArch:       %q,\n", c.Arch)
+		fmt.Fprintf(&sb, "\t\tFlag:       %q,\n", c.Mode.Flag)
+		fmt.Fprintf(&sb, "\t\tDTB:        %v,\n", c.Mode.DTB)
+		fmt.Fprintf(&sb, "\t\tCompressed: %v,\n", c.Mode.Compressed)
+		sb.WriteString("\t})\n")
+		sb.WriteString("}\n\n")
+	}
+
+	return format.Source([]byte(sb.String()))
+}
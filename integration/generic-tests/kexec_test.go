@@ -12,6 +12,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"testing"
 	"time"
 
@@ -19,8 +20,32 @@ import (
 	"github.com/hugelgupf/vmtest/qemu"
 	"github.com/hugelgupf/vmtest/testtmp"
 	"github.com/u-root/u-root/pkg/uroot"
+	uvmtest "github.com/u-root/u-root/pkg/vmtest"
+	"github.com/u-root/u-root/pkg/vmtest/kernels"
 )
 
+// kexecTestKernels is the kernel matrix TestMountKexecMatrix,
+// TestMountKexecLoadMatrix, and TestKexecLinuxImageCfgFileMatrix run
+// against, in addition to the single VMTEST_KERNEL every other test in
+// this file uses. Each entry mirrors a kernel.org longterm release so
+// that version-specific regressions in kexec_file_load/kexec_load show
+// up before they reach users on those kernels.
+//
+// TODO: storage.googleapis.com/uroot-vmtest-kernels doesn't exist yet;
+// until a real artifact bucket is stood up and these URLs/SHA256 values
+// point at it, every one of these fetches will fail and the matrix
+// tests will skip (each subtest's skip message carries the fetch error,
+// so this isn't silent). Populate both before relying on
+// this matrix to catch version-specific regressions. matrix_runner.go's
+// kernelURL follows the same placeholder pattern.
+var kexecTestKernels = []kernels.KernelSpec{
+	{Name: "5.10", URL: "https://storage.googleapis.com/uroot-vmtest-kernels/linux-5.10.bzImage", SHA256: "", Arch: "amd64"},
+	{Name: "5.15", URL: "https://storage.googleapis.com/uroot-vmtest-kernels/linux-5.15.bzImage", SHA256: "", Arch: "amd64"},
+	{Name: "6.1", URL: "https://storage.googleapis.com/uroot-vmtest-kernels/linux-6.1.bzImage", SHA256: "", Arch: "amd64"},
+	{Name: "6.4", URL: "https://storage.googleapis.com/uroot-vmtest-kernels/linux-6.4.bzImage", SHA256: "", Arch: "amd64"},
+	{Name: "6.6", URL: "https://storage.googleapis.com/uroot-vmtest-kernels/linux-6.6.bzImage", SHA256: "", Arch: "amd64"},
+}
+
 // TestMountKexec tests that kexec occurs correctly by checking the kernel cmdline.
 // This is possible because the generic initramfs ensures that we mount the
 // testdata directory containing the initramfs and kernel used in the VM.
@@ -231,3 +256,267 @@ func TestKexecLinuxImageCfgFile(t *testing.T) {
 		t.Errorf("Wait: %v", err)
 	}
 }
+
+// TestMountKexecMatrix runs TestMountKexec's kexec_file_load scenario
+// against a matrix of kernel versions instead of the single kernel named
+// by VMTEST_KERNEL, so that `go test -run TestMountKexecMatrix/6.6` can
+// target one kernel version and CI can report per-kernel pass/fail.
+func TestMountKexecMatrix(t *testing.T) {
+	vmtest.SkipIfNotArch(t, qemu.ArchAMD64, qemu.ArchArm64)
+
+	uvmtest.RunAcrossKernels(t, kexecTestKernels, func(t *testing.T, kernelPath string) {
+		testCmds := []string{
+			"var CMDLINE = (cat /proc/cmdline)",
+			"var SUFFIX = $CMDLINE[-7..]",
+			"echo SAW $SUFFIX",
+			"kexec -i /testdata/initramfs.cpio -c $CMDLINE' KEXEC=Y' /kernel",
+		}
+
+		vm := vmtest.StartVMAndRunCmds(t, testCmds,
+			vmtest.WithMergedInitramfs(uroot.Opts{
+				Commands: uroot.BusyBoxCmds(
+					"github.com/u-root/u-root/cmds/core/cat",
+					"github.com/u-root/u-root/cmds/core/kexec",
+					"github.com/u-root/u-root/cmds/core/shutdown",
+				),
+				ExtraFiles: []string{
+					fmt.Sprintf("%s:kernel", kernelPath),
+				},
+			}),
+			vmtest.WithQEMUFn(
+				qemu.WithVMTimeout(time.Minute),
+				qemu.ArbitraryArgs("-m", "8192"),
+			),
+			// The initramfs will be placed in shared dir, so in the VM
+			// it's available at /testdata/initramfs.cpio.
+			vmtest.WithSharedDir(testtmp.TempDir(t)),
+		)
+
+		if _, err := vm.Console.ExpectString("SAW KEXEC=Y"); err != nil {
+			t.Fatal(err)
+		}
+		if err := vm.Kill(); err != nil {
+			t.Errorf("Kill: %v", err)
+		}
+		_ = vm.Wait()
+	})
+}
+
+// TestMountKexecLoadMatrix runs TestMountKexecLoad's kexec_load syscall
+// scenario against the kernel matrix, the same way TestMountKexecMatrix
+// does for TestMountKexec's kexec_file_load scenario.
+func TestMountKexecLoadMatrix(t *testing.T) {
+	vmtest.SkipIfNotArch(t, qemu.ArchAMD64, qemu.ArchArm64)
+
+	gzipP, err := exec.LookPath("gzip")
+	if err != nil {
+		t.Skipf("no gzip found, skip it as it won't be able to de-compress kernel")
+	}
+
+	uvmtest.RunAcrossKernels(t, kexecTestKernels, func(t *testing.T, kernelPath string) {
+		testCmds := []string{
+			"var CMDLINE = (cat /proc/cmdline)",
+			"var SUFFIX = $CMDLINE[-7..]",
+			"echo SAW $SUFFIX",
+			"kexec -d -i /testdata/initramfs.cpio --loadsyscall -c $CMDLINE' KEXEC=Y' /kernel",
+		}
+
+		vm := vmtest.StartVMAndRunCmds(t, testCmds,
+			vmtest.WithMergedInitramfs(uroot.Opts{
+				Commands: uroot.BusyBoxCmds(
+					"github.com/u-root/u-root/cmds/core/cat",
+					"github.com/u-root/u-root/cmds/core/kexec",
+					"github.com/u-root/u-root/cmds/core/shutdown",
+				),
+				ExtraFiles: []string{
+					fmt.Sprintf("%s:kernel", kernelPath),
+					gzipP,
+				},
+			}),
+			vmtest.WithQEMUFn(
+				qemu.WithVMTimeout(time.Minute),
+				qemu.ArbitraryArgs("-m", "8192"),
+			),
+			// The initramfs will be placed in shared dir, so in the VM
+			// it's available at /testdata/initramfs.cpio.
+			vmtest.WithSharedDir(testtmp.TempDir(t)),
+		)
+
+		if _, err := vm.Console.ExpectString("SAW KEXEC=Y"); err != nil {
+			t.Error(err)
+		}
+		if err := vm.Kill(); err != nil {
+			t.Errorf("Kill: %v", err)
+		}
+		_ = vm.Wait()
+	})
+}
+
+// TestKexecLinuxImageCfgFileMatrix runs TestKexecLinuxImageCfgFile's
+// -I linux_image_cfg.json scenario against the kernel matrix.
+func TestKexecLinuxImageCfgFileMatrix(t *testing.T) {
+	vmtest.SkipIfNotArch(t, qemu.ArchAMD64, qemu.ArchArm64)
+
+	uvmtest.RunAcrossKernels(t, kexecTestKernels, func(t *testing.T, kernelPath string) {
+		dir := t.TempDir()
+		cfg := []byte("{ \"InitrdPath\": \"/testdata/initramfs.cpio\", \"KernelPath\": \"/kernel\", \"Cmdline\": \"/proc/cmdline\", \"Name\": \"testloadconfig\" }")
+		cfgFile := filepath.Join(dir, "linux_image_cfg.json")
+		if err := os.WriteFile(cfgFile, cfg, 0777); err != nil {
+			t.Fatalf("Failed to setup test cfg file: %v", err)
+		}
+
+		testCmds := []string{
+			"echo kexecloadresult ?(kexec -d -l -I /linux_image_cfg.json)",
+		}
+		vm := vmtest.StartVMAndRunCmds(t, testCmds,
+			vmtest.WithMergedInitramfs(uroot.Opts{
+				Commands: uroot.BusyBoxCmds(
+					"github.com/u-root/u-root/cmds/core/cat",
+					"github.com/u-root/u-root/cmds/core/echo",
+					"github.com/u-root/u-root/cmds/core/kexec",
+				),
+				ExtraFiles: []string{
+					fmt.Sprintf("%s:kernel", kernelPath),
+					fmt.Sprintf("%s:linux_image_cfg.json", cfgFile),
+				},
+			}),
+			vmtest.WithQEMUFn(
+				qemu.WithVMTimeout(time.Minute),
+				qemu.ArbitraryArgs("-m", "8192"),
+			),
+			// The initramfs will be placed in shared dir, so in the VM
+			// it's available at /testdata/initramfs.cpio.
+			vmtest.WithSharedDir(testtmp.TempDir(t)),
+		)
+
+		if _, err := vm.Console.ExpectString("kexecloadresult $ok"); err != nil {
+			t.Error(err)
+		}
+		if err := vm.Wait(); err != nil {
+			t.Errorf("Wait: %v", err)
+		}
+	})
+}
+
+// TestMountKexecSSH is like TestMountKexec, but drives the guest over an
+// SSH transport instead of scraping the QEMU serial console, which mixes
+// kernel log output (kexec itself is noisy on dmesg) with test output.
+// It only loads the kernel with kexec -l and checks that the command
+// succeeds; it does not reboot into the loaded kernel.
+func TestMountKexecSSH(t *testing.T) {
+	vmtest.SkipIfNotArch(t, qemu.ArchAMD64, qemu.ArchArm64)
+
+	keyDir := t.TempDir()
+	keyPath := filepath.Join(keyDir, "id_test")
+	if out, err := exec.Command("ssh-keygen", "-t", "ed25519", "-N", "", "-f", keyPath).CombinedOutput(); err != nil {
+		t.Skipf("ssh-keygen not available, skipping SSH transport test: %v: %s", err, out)
+	}
+	testCmds := []string{
+		"ip link set lo up",
+		"ip link set eth0 up",
+		"sshd -keys=/authorized_keys",
+	}
+
+	vm := vmtest.StartVMAndRunCmds(t, testCmds,
+		vmtest.WithMergedInitramfs(uroot.Opts{
+			Commands: uroot.BusyBoxCmds(
+				"github.com/u-root/u-root/cmds/core/cat",
+				"github.com/u-root/u-root/cmds/core/ip",
+				"github.com/u-root/u-root/cmds/core/kexec",
+				"github.com/u-root/u-root/cmds/exp/sshd",
+			),
+			ExtraFiles: []string{
+				fmt.Sprintf("%s:kernel", os.Getenv("VMTEST_KERNEL")),
+				fmt.Sprintf("%s:authorized_keys", keyPath+".pub"),
+			},
+		}),
+		vmtest.WithQEMUFn(
+			qemu.WithVMTimeout(time.Minute),
+			qemu.ArbitraryArgs("-netdev", "user,id=net0,hostfwd=tcp::2222-:22", "-device", "e1000,netdev=net0"),
+		),
+		vmtest.WithSharedDir(testtmp.TempDir(t)),
+	)
+	defer func() {
+		_ = vm.Kill()
+		_ = vm.Wait()
+	}()
+
+	ssh, err := uvmtest.DialGuestSSH("127.0.0.1:2222", uvmtest.WithSSH("root", keyPath), 30*time.Second)
+	if err != nil {
+		t.Fatalf("DialGuestSSH: %v", err)
+	}
+	defer ssh.Close()
+
+	out, err := ssh.Run("kexec -l -i /testdata/initramfs.cpio -c $(cat /proc/cmdline)' KEXEC=Y' /kernel")
+	if err != nil {
+		t.Fatalf("kexec over SSH: %v: %s", err, out)
+	}
+}
+
+// TestMountKexecSSHExpect is like TestMountKexecSSH, but drives the kexec
+// load over the GuestSSH.Expect PTY session rather than Run, the same way
+// TestMountKexec drives the serial console via vm.Console.ExpectString:
+// it sends the kexec command interactively and waits for output echoed
+// back over the PTY, exercising the goexpect/PTY integration that Run
+// never touches.
+func TestMountKexecSSHExpect(t *testing.T) {
+	vmtest.SkipIfNotArch(t, qemu.ArchAMD64, qemu.ArchArm64)
+
+	keyDir := t.TempDir()
+	keyPath := filepath.Join(keyDir, "id_test")
+	if out, err := exec.Command("ssh-keygen", "-t", "ed25519", "-N", "", "-f", keyPath).CombinedOutput(); err != nil {
+		t.Skipf("ssh-keygen not available, skipping SSH transport test: %v: %s", err, out)
+	}
+	testCmds := []string{
+		"ip link set lo up",
+		"ip link set eth0 up",
+		"sshd -keys=/authorized_keys",
+	}
+
+	vm := vmtest.StartVMAndRunCmds(t, testCmds,
+		vmtest.WithMergedInitramfs(uroot.Opts{
+			Commands: uroot.BusyBoxCmds(
+				"github.com/u-root/u-root/cmds/core/cat",
+				"github.com/u-root/u-root/cmds/core/ip",
+				"github.com/u-root/u-root/cmds/core/kexec",
+				"github.com/u-root/u-root/cmds/exp/sshd",
+			),
+			ExtraFiles: []string{
+				fmt.Sprintf("%s:kernel", os.Getenv("VMTEST_KERNEL")),
+				fmt.Sprintf("%s:authorized_keys", keyPath+".pub"),
+			},
+		}),
+		vmtest.WithQEMUFn(
+			qemu.WithVMTimeout(time.Minute),
+			qemu.ArbitraryArgs("-netdev", "user,id=net0,hostfwd=tcp::2223-:22", "-device", "e1000,netdev=net0"),
+		),
+		vmtest.WithSharedDir(testtmp.TempDir(t)),
+	)
+	defer func() {
+		_ = vm.Kill()
+		_ = vm.Wait()
+	}()
+
+	ssh, err := uvmtest.DialGuestSSH("127.0.0.1:2223", uvmtest.WithSSH("root", keyPath), 30*time.Second)
+	if err != nil {
+		t.Fatalf("DialGuestSSH: %v", err)
+	}
+	defer ssh.Close()
+
+	e, _, err := ssh.Expect(30 * time.Second)
+	if err != nil {
+		t.Fatalf("Expect: %v", err)
+	}
+	defer e.Close()
+
+	const marker = "KEXEC_LOADED_OK"
+	cmd := fmt.Sprintf("kexec -l -i /testdata/initramfs.cpio -c $(cat /proc/cmdline)' KEXEC=Y' /kernel && echo %s\n", marker)
+	if err := e.Send(cmd); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	// The PTY echoes the typed command, which also contains marker, so
+	// only a line that consists of marker alone counts as echo's output.
+	if _, _, err := e.Expect(regexp.MustCompile("(?m)^"+marker+"\r?$"), 30*time.Second); err != nil {
+		t.Fatalf("Expect %q over SSH PTY session: %v", marker, err)
+	}
+}
@@ -0,0 +1,29 @@
+// Copyright 2024 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !race
+// +build !race
+
+package integration
+
+//go:generate go run ./gen
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+)
+
+// TestMain fails fast if matrix_generated_test.go is stale relative to
+// matrix.hujson, so a manifest edit that nobody regenerated from doesn't
+// silently test the old matrix.
+func TestMain(m *testing.M) {
+	cmd := exec.Command("go", "run", "./gen", "-check")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		os.Stderr.Write(out)
+		os.Stderr.WriteString("matrix_generated_test.go is out of date; run `go generate ./...`\n")
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
@@ -0,0 +1,112 @@
+// Copyright 2024 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package integration
+
+import (
+	"fmt"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/hugelgupf/vmtest"
+	"github.com/hugelgupf/vmtest/qemu"
+	"github.com/hugelgupf/vmtest/testtmp"
+	"github.com/u-root/u-root/pkg/uroot"
+	"github.com/u-root/u-root/pkg/vmtest/kernels"
+)
+
+// matrixCombination is one row of the generated kexec test matrix; see
+// matrix.hujson and matrix_generated_test.go.
+type matrixCombination struct {
+	Kernel     string
+	Arch       string
+	Flag       string
+	DTB        bool
+	Compressed bool
+}
+
+// kernelURL returns the download URL for a kernel/arch combination, in
+// the same bucket as kexecTestKernels but under a per-arch directory.
+//
+// TODO: this bucket doesn't exist yet (see the TODO on kexecTestKernels
+// in kexec_test.go); every combination fetches from here with an empty
+// SHA256 until a real artifact bucket is populated with checksummed
+// builds, so the generated matrix currently only proves it skips
+// cleanly, not that kexec works on any of these kernels.
+func kernelURL(kernel, arch string) string {
+	return fmt.Sprintf("https://storage.googleapis.com/uroot-vmtest-kernels/%s/linux-%s.bzImage", arch, kernel)
+}
+
+// runMatrixCombination drives a single generated matrix test: it fetches
+// the combination's kernel, boots a kexec-capable guest for it, and
+// kexecs into it using the mode described by c.
+func runMatrixCombination(t *testing.T, c matrixCombination) {
+	t.Helper()
+	vmtest.SkipIfNotArch(t, qemu.Arch(c.Arch))
+
+	spec := kernels.KernelSpec{
+		Name:   c.Kernel,
+		URL:    kernelURL(c.Kernel, c.Arch),
+		SHA256: "", // filled in once real checksums are published for this matrix
+		Arch:   c.Arch,
+	}
+	kernelPath, err := kernels.Fetch(spec)
+	if err != nil {
+		t.Skipf("could not fetch kernel %s/%s: %v", c.Arch, c.Kernel, err)
+	}
+
+	extraFiles := []string{fmt.Sprintf("%s:kernel", kernelPath)}
+	cmds := []string{
+		"github.com/u-root/u-root/cmds/core/cat",
+		"github.com/u-root/u-root/cmds/core/kexec",
+	}
+	testCmds := []string{
+		"var CMDLINE = (cat /proc/cmdline)",
+		"var SUFFIX = $CMDLINE[-7..]",
+		"echo SAW $SUFFIX",
+	}
+
+	if c.Compressed {
+		gzipP, err := exec.LookPath("gzip")
+		if err != nil {
+			t.Skipf("no gzip found, skip it as it won't be able to de-compress kernel")
+		}
+		extraFiles = append(extraFiles, gzipP)
+	}
+	if c.DTB {
+		cmds = append(cmds, "github.com/u-root/u-root/cmds/core/cp")
+		testCmds = append(testCmds, "cp /sys/firmware/fdt /tmp/userfdt")
+	}
+
+	kexecCmd := "kexec -i /testdata/initramfs.cpio"
+	if c.DTB {
+		kexecCmd = "kexec --dtb /tmp/userfdt -i /testdata/initramfs.cpio"
+	}
+	if c.Flag != "" {
+		kexecCmd += " " + c.Flag
+	}
+	kexecCmd += " -c $CMDLINE' KEXEC=Y' /kernel"
+	testCmds = append(testCmds, kexecCmd)
+
+	vm := vmtest.StartVMAndRunCmds(t, testCmds,
+		vmtest.WithMergedInitramfs(uroot.Opts{
+			Commands:   uroot.BusyBoxCmds(cmds...),
+			ExtraFiles: extraFiles,
+		}),
+		vmtest.WithQEMUFn(
+			qemu.WithVMTimeout(time.Minute),
+			qemu.ArbitraryArgs("-m", "8192"),
+		),
+		vmtest.WithSharedDir(testtmp.TempDir(t)),
+	)
+
+	if _, err := vm.Console.ExpectString("SAW KEXEC=Y"); err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.Kill(); err != nil {
+		t.Errorf("Kill: %v", err)
+	}
+	_ = vm.Wait()
+}
@@ -0,0 +1,150 @@
+// Copyright 2024 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/u-root/u-root/pkg/qemu"
+	"github.com/u-root/u-root/pkg/vmtest/guestimage"
+)
+
+// TestGuestImageBoot boots one distro from testdata/distros.json end to
+// end: fetch the qcow2 image, render a cloud-init seed ISO, start QEMU,
+// and confirm sshd comes up and the package manager ran. It's opt-in via
+// VMTEST_GUESTIMAGE because it downloads a full distro image on first
+// run. TestDhclientAgainstDistroServer in generic-tests uses the same
+// pieces to run a distro DHCP server opposite a u-root dhclient guest.
+func TestGuestImageBoot(t *testing.T) {
+	if os.Getenv("VMTEST_GUESTIMAGE") == "" {
+		t.Skip("set VMTEST_GUESTIMAGE=1 to run guest image boot tests")
+	}
+	if _, err := exec.LookPath("qemu-system-x86_64"); err != nil {
+		t.Skip("qemu-system-x86_64 not found")
+	}
+	if _, err := exec.LookPath("genisoimage"); err != nil {
+		t.Skip("genisoimage not found")
+	}
+
+	distros, err := guestimage.LoadManifest("testdata/distros.json")
+	if err != nil {
+		t.Fatalf("LoadManifest: %v", err)
+	}
+	d, err := guestimage.Find(distros, "alpine-3.19")
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+
+	imagePath, err := guestimage.Fetch(d)
+	if err != nil {
+		t.Skipf("could not fetch guest image %s: %v", d.Name, err)
+	}
+
+	seedISO, err := guestimage.WriteSeedISO(t.TempDir(), d, []string{"iproute2"})
+	if err != nil {
+		t.Fatalf("WriteSeedISO: %v", err)
+	}
+
+	sshFwd, err := qemu.NewHostFwd("ssh", 22)
+	if err != nil {
+		t.Fatalf("NewHostFwd: %v", err)
+	}
+
+	proc, vm, err := guestimage.BootAndDial(d, imagePath, seedISO, guestimage.BootOptions{
+		SSHHostPort: sshFwd.HostPort(),
+	}, 2*time.Minute)
+	if err != nil {
+		t.Fatalf("BootAndDial: %v", err)
+	}
+	defer func() {
+		_ = proc.Kill()
+		_ = proc.Wait()
+	}()
+	defer vm.Close()
+
+	out, err := vm.SSHRun("ip -V")
+	if err != nil {
+		t.Errorf("ip -V over SSH: %v: %s", err, out)
+	}
+}
+
+// TestTopologySegmentSecondNIC boots a distro guest with its usual SSH
+// control NIC plus a second NIC attached to an isolated
+// qemu.Topology segment with a fixed MAC and packet capture enabled,
+// exercising the multi-NIC topology API end to end: we bring the second
+// NIC up over SSH, generate some traffic on it, and check that the
+// capture actually recorded something.
+func TestTopologySegmentSecondNIC(t *testing.T) {
+	if os.Getenv("VMTEST_GUESTIMAGE") == "" {
+		t.Skip("set VMTEST_GUESTIMAGE=1 to run guest image boot tests")
+	}
+	if _, err := exec.LookPath("qemu-system-x86_64"); err != nil {
+		t.Skip("qemu-system-x86_64 not found")
+	}
+	if _, err := exec.LookPath("genisoimage"); err != nil {
+		t.Skip("genisoimage not found")
+	}
+
+	distros, err := guestimage.LoadManifest("testdata/distros.json")
+	if err != nil {
+		t.Fatalf("LoadManifest: %v", err)
+	}
+	d, err := guestimage.Find(distros, "alpine-3.19")
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+
+	imagePath, err := guestimage.Fetch(d)
+	if err != nil {
+		t.Skipf("could not fetch guest image %s: %v", d.Name, err)
+	}
+	seedISO, err := guestimage.WriteSeedISO(t.TempDir(), d, []string{"iproute2"})
+	if err != nil {
+		t.Fatalf("WriteSeedISO: %v", err)
+	}
+
+	sshFwd, err := qemu.NewHostFwd("ssh", 22)
+	if err != nil {
+		t.Fatalf("NewHostFwd: %v", err)
+	}
+
+	top := qemu.NewTopology()
+	seg := top.NewSegment("lan")
+	pcapFile := filepath.Join(t.TempDir(), "lan.pcap")
+	seg.CapturePackets(pcapFile)
+	nicArgs := seg.NIC("52:54:00:12:34:56").Args("amd64", "vm1")
+
+	proc, vm, err := guestimage.BootAndDial(d, imagePath, seedISO, guestimage.BootOptions{
+		SSHHostPort: sshFwd.HostPort(),
+		ExtraArgs:   nicArgs,
+	}, 2*time.Minute)
+	if err != nil {
+		t.Fatalf("BootAndDial: %v", err)
+	}
+	defer func() {
+		_ = proc.Kill()
+		_ = proc.Wait()
+	}()
+	defer vm.Close()
+
+	// eth1 is the second NIC; bringing it up and pinging the segment's
+	// broadcast address is enough to generate ARP traffic for the
+	// capture to record even with no other VM on the segment.
+	if out, err := vm.SSHRun("ip link set eth1 up && ping -c1 -W1 -b 230.0.0.1 || true"); err != nil {
+		t.Fatalf("bringing up eth1 over SSH: %v: %s", err, out)
+	}
+
+	info, err := os.Stat(pcapFile)
+	if err != nil {
+		t.Fatalf("stat pcap file: %v", err)
+	}
+	if info.Size() == 0 {
+		t.Error("pcap capture file is empty; expected eth1 traffic to be recorded")
+	}
+}
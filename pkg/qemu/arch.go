@@ -0,0 +1,38 @@
+// Copyright 2024 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package qemu
+
+// netdevArgs returns the -netdev/-device QEMU arguments to attach a NIC
+// with the given id to a guest built for arch (a GOARCH value), so that
+// callers like NIC.Args don't have to hardcode the amd64 e1000 wiring.
+// netdev is the backend spec without an id= clause (e.g.
+// "user" or "socket,mcast=230.0.0.1:1234"); netdevArgs appends the id=
+// clause itself so callers can't accidentally duplicate it. If mac is
+// non-empty, it's set on the -device line, which is where QEMU expects a
+// NIC's MAC address, not via -global.
+func netdevArgs(arch, netdev, id, mac string) []string {
+	device := deviceModel(arch)
+	if mac != "" {
+		device += ",mac=" + mac
+	}
+	return []string{
+		"-netdev", netdev + ",id=" + id,
+		"-device", device + ",netdev=" + id,
+	}
+}
+
+// deviceModel returns the QEMU -device model used to attach a NIC to a
+// guest built for arch.
+func deviceModel(arch string) string {
+	switch arch {
+	case "arm", "arm64", "riscv64":
+		// The "virt" machine used for arm/arm64/riscv64 guests
+		// exposes virtio-net-device rather than the PCI e1000 NIC
+		// amd64 guests use.
+		return "virtio-net-device"
+	default:
+		return "e1000"
+	}
+}
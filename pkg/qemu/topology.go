@@ -0,0 +1,139 @@
+// Copyright 2024 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package qemu
+
+import (
+	"fmt"
+	"net"
+)
+
+// Topology builds a multi-VM QEMU network layout richer than NewNetwork's
+// single shared segment: isolated L2 segments so that only some VMs can
+// see each other, multiple NICs per VM with fixed MAC addresses, host
+// port forwarding with automatically allocated ports, and per-segment
+// packet capture for post-mortem debugging.
+//
+// The zero Topology is an empty topology ready to use; NewTopology
+// returns one.
+type Topology struct {
+	segments []*Segment
+}
+
+// NewTopology returns an empty Topology. Add segments to it with
+// NewSegment.
+func NewTopology() *Topology {
+	return &Topology{}
+}
+
+// Segment is an isolated L2 network that VMs can be attached to via
+// NIC. VMs on different segments cannot see each other's traffic, which
+// lets a test model e.g. a DHCP relay or a router between two subnets.
+type Segment struct {
+	name     string
+	id       string
+	pcapFile string
+}
+
+// NewSegment adds a new isolated L2 segment named name to the topology
+// and returns it. name must be unique within the topology; it's used to
+// derive the QEMU netdev id and should be short and identifier-safe.
+func (t *Topology) NewSegment(name string) *Segment {
+	s := &Segment{name: name, id: "seg-" + name}
+	t.segments = append(t.segments, s)
+	return s
+}
+
+// CapturePackets configures the segment to record all traffic crossing it
+// to a pcap file at path, using QEMU's filter-dump object. Call this
+// before starting any VM attached to the segment.
+func (s *Segment) CapturePackets(path string) {
+	s.pcapFile = path
+}
+
+// NIC describes one network interface to attach a VM to, tying it to a
+// segment and, optionally, a fixed MAC address (useful for DHCP
+// client-id tests that key off the MAC).
+type NIC struct {
+	segment *Segment
+	mac     string
+}
+
+// NIC returns a NIC attaching a VM to s. If mac is empty, QEMU assigns a
+// MAC address automatically.
+func (s *Segment) NIC(mac string) NIC {
+	return NIC{segment: s, mac: mac}
+}
+
+// Args returns the -netdev/-device/-object arguments that attach a VM to
+// n, using vmID to keep netdev ids unique across VMs sharing a segment.
+func (n NIC) Args(arch, vmID string) []string {
+	netdevID := fmt.Sprintf("%s-%s", n.segment.id, vmID)
+	netdev := fmt.Sprintf("socket,mcast=230.0.0.1:%s", segmentPort(n.segment.id))
+
+	args := netdevArgs(arch, netdev, netdevID, n.mac)
+	if n.segment.pcapFile != "" {
+		args = append(args,
+			"-object", fmt.Sprintf("filter-dump,id=%s-dump,netdev=%s,file=%s", netdevID, netdevID, n.segment.pcapFile),
+		)
+	}
+	return args
+}
+
+// segmentPort derives a stable multicast port for a segment id so that
+// every VM attached to the same segment rendezvous on the same port.
+// Different segment ids hash into 10000 ports, so two segments can
+// still land on the same port.
+func segmentPort(id string) string {
+	h := uint32(2049)
+	for i := 0; i < len(id); i++ {
+		h = h*31 + uint32(id[i])
+	}
+	return fmt.Sprintf("%d", 20000+(h%10000))
+}
+
+// HostFwd declares a host-to-guest port forward, allocating a free host
+// port automatically. Name labels the forward, e.g. "ssh"; the allocated
+// port is read back from the HostFwd itself with HostPort. There is no
+// lookup of a forward by name.
+type HostFwd struct {
+	Name      string
+	GuestPort int
+	hostPort  int
+}
+
+// NewHostFwd allocates a free host port and returns a HostFwd forwarding
+// it to guestPort inside the VM. Register the result's Arg on the VM's
+// user-mode netdev.
+func NewHostFwd(name string, guestPort int) (HostFwd, error) {
+	port, err := allocatePort()
+	if err != nil {
+		return HostFwd{}, fmt.Errorf("allocating host port for %q: %w", name, err)
+	}
+	return HostFwd{Name: name, GuestPort: guestPort, hostPort: port}, nil
+}
+
+// HostPort returns the host port this forward was allocated.
+func (h HostFwd) HostPort() int {
+	return h.hostPort
+}
+
+// Arg returns the hostfwd= clause to append to a "-netdev user,..."
+// argument.
+func (h HostFwd) Arg() string {
+	return fmt.Sprintf("hostfwd=tcp::%d-:%d", h.hostPort, h.GuestPort)
+}
+
+// allocatePort finds a free TCP port by briefly binding to port 0 and
+// reading back what the kernel assigned, then releasing it. This is
+// inherently racy against other processes but is the usual trick for
+// pre-allocating a port to hand to a child process (here, QEMU).
+func allocatePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
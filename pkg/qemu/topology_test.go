@@ -0,0 +1,69 @@
+// Copyright 2024 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package qemu
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSegmentsGetDistinctPorts(t *testing.T) {
+	top := NewTopology()
+	a := top.NewSegment("a")
+	b := top.NewSegment("b")
+
+	if segmentPort(a.id) == segmentPort(b.id) {
+		t.Errorf("segments %q and %q rendezvous on the same port", a.id, b.id)
+	}
+}
+
+func TestNICArgsIncludesMACAndPcap(t *testing.T) {
+	top := NewTopology()
+	seg := top.NewSegment("lan")
+	seg.CapturePackets("/tmp/lan.pcap")
+
+	args := seg.NIC("52:54:00:12:34:56").Args("amd64", "vm1")
+
+	var netdevArg, deviceArg string
+	for i, a := range args {
+		switch a {
+		case "-netdev":
+			netdevArg = args[i+1]
+		case "-device":
+			deviceArg = args[i+1]
+		}
+	}
+
+	if strings.Count(netdevArg, "id=") != 1 {
+		t.Errorf("-netdev %q has %d id= clauses, want exactly 1", netdevArg, strings.Count(netdevArg, "id="))
+	}
+	if !strings.Contains(deviceArg, "mac=52:54:00:12:34:56") {
+		t.Errorf("-device %q, want it to set mac= on the device (not via -global)", deviceArg)
+	}
+	for _, a := range args {
+		if a == "-global" {
+			t.Errorf("Args() = %v, -global is not how QEMU sets a NIC's MAC", args)
+		}
+	}
+
+	joined := strings.Join(args, " ")
+	if !strings.Contains(joined, "/tmp/lan.pcap") {
+		t.Errorf("Args() = %q, want it to contain the pcap file path", joined)
+	}
+}
+
+func TestNewHostFwdAllocatesDistinctPorts(t *testing.T) {
+	ssh, err := NewHostFwd("ssh", 22)
+	if err != nil {
+		t.Fatalf("NewHostFwd: %v", err)
+	}
+	http, err := NewHostFwd("http", 80)
+	if err != nil {
+		t.Fatalf("NewHostFwd: %v", err)
+	}
+	if ssh.HostPort() == http.HostPort() {
+		t.Errorf("NewHostFwd allocated the same host port twice: %d", ssh.HostPort())
+	}
+}
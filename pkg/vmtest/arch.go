@@ -0,0 +1,23 @@
+// Copyright 2024 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vmtest
+
+import "testing"
+
+// SkipIfNotArch skips the test unless TestArch() is one of archs. It
+// mirrors github.com/hugelgupf/vmtest's SkipIfNotArch so tests that have
+// outgrown a single hardcoded "amd64 only" check can list every arch
+// they support instead.
+func SkipIfNotArch(t *testing.T, archs ...string) {
+	t.Helper()
+
+	arch := TestArch()
+	for _, a := range archs {
+		if arch == a {
+			return
+		}
+	}
+	t.Skipf("test not supported on %s", arch)
+}
@@ -0,0 +1,94 @@
+// Copyright 2024 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guestimage
+
+import (
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// BootOptions configures Boot.
+type BootOptions struct {
+	// Mem overrides the distro's default memory size, in MiB, if
+	// nonzero.
+	Mem int
+
+	// SSHHostPort is the host port forwarded to the guest's sshd
+	// (port 22). Allocate it with e.g. qemu.NewHostFwd("ssh", 22).
+	SSHHostPort int
+
+	// ExtraArgs are appended to the QEMU command line in addition to
+	// the default user-mode SSH NIC, e.g. a second
+	// pkg/qemu.Topology.Segment NIC for isolated multi-VM tests.
+	ExtraArgs []string
+}
+
+// Process is a running guest QEMU process booted from a qcow2 image.
+type Process struct {
+	cmd *exec.Cmd
+}
+
+// Boot starts d from the qcow2 image at imagePath, with the cloud-init
+// seed ISO at seedISOPath attached so the image's first boot configures
+// GuestUser/GuestPassword and installs its packages. Boot returns once
+// the QEMU process has been started; it does not wait for the guest to
+// finish booting sshd. Use DialSSH against "127.0.0.1:<SSHHostPort>" for
+// that.
+func Boot(d Distro, imagePath, seedISOPath string, opts BootOptions) (*Process, error) {
+	mem := d.Mem
+	if opts.Mem != 0 {
+		mem = opts.Mem
+	}
+
+	args := []string{
+		"-m", fmt.Sprintf("%d", mem),
+		"-drive", fmt.Sprintf("file=%s,if=virtio,format=qcow2", imagePath),
+		"-drive", fmt.Sprintf("file=%s,if=virtio,format=raw,readonly=on", seedISOPath),
+		"-nographic",
+		"-serial", "null",
+	}
+	// A user-mode NIC with sshd forwarded to the host always comes
+	// along, so DialSSH/BootAndDial keep working regardless of what
+	// other NICs ExtraArgs attaches (e.g. a pkg/qemu.Topology segment).
+	args = append(args,
+		"-netdev", fmt.Sprintf("user,id=net0,hostfwd=tcp::%d-:22", opts.SSHHostPort),
+		"-device", "virtio-net-pci,netdev=net0",
+	)
+	args = append(args, opts.ExtraArgs...)
+
+	cmd := exec.Command("qemu-system-x86_64", args...)
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting qemu for guest image %s: %w", d.Name, err)
+	}
+	return &Process{cmd: cmd}, nil
+}
+
+// Kill terminates the guest QEMU process.
+func (p *Process) Kill() error {
+	return p.cmd.Process.Kill()
+}
+
+// Wait waits for the guest QEMU process to exit.
+func (p *Process) Wait() error {
+	return p.cmd.Wait()
+}
+
+// BootAndDial boots d and waits up to timeout for its sshd to come up,
+// returning a connected VM. It's the common case of Boot followed by
+// DialSSH.
+func BootAndDial(d Distro, imagePath, seedISOPath string, opts BootOptions, timeout time.Duration) (*Process, *VM, error) {
+	p, err := Boot(d, imagePath, seedISOPath, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	vm, err := DialSSH(fmt.Sprintf("127.0.0.1:%d", opts.SSHHostPort), timeout)
+	if err != nil {
+		_ = p.Kill()
+		return nil, nil, err
+	}
+	return p, vm, nil
+}
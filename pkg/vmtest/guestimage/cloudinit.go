@@ -0,0 +1,117 @@
+// Copyright 2024 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guestimage
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// GuestUser and GuestPassword are the credentials cloud-init configures on
+// every guest booted via this package, so tests can dial in over SSH
+// without having to plumb a generated password through.
+const (
+	GuestUser     = "vmtest"
+	GuestPassword = "vmtest"
+)
+
+// installCmd returns the shell command used to non-interactively install
+// packages with d's package manager.
+func installCmd(d Distro, packages []string) (string, error) {
+	switch d.PackageManager {
+	case "apt":
+		return fmt.Sprintf("apt-get update && apt-get install -y %s", joinPkgs(packages)), nil
+	case "apk":
+		return fmt.Sprintf("apk add --no-cache %s", joinPkgs(packages)), nil
+	case "dnf":
+		return fmt.Sprintf("dnf install -y %s", joinPkgs(packages)), nil
+	case "zypper":
+		return fmt.Sprintf("zypper --non-interactive install %s", joinPkgs(packages)), nil
+	default:
+		return "", fmt.Errorf("unknown package manager %q for distro %q", d.PackageManager, d.Name)
+	}
+}
+
+// runcmdLines renders cmds as a YAML sequence with one
+// "[ sh, -c, cmd ]" runcmd entry per command.
+func runcmdLines(cmds []string) string {
+	out := ""
+	for i, c := range cmds {
+		if i > 0 {
+			out += "\n"
+		}
+		out += fmt.Sprintf("  - [ sh, -c, %q ]", c)
+	}
+	return out
+}
+
+func joinPkgs(packages []string) string {
+	out := ""
+	for i, p := range packages {
+		if i > 0 {
+			out += " "
+		}
+		out += p
+	}
+	return out
+}
+
+// WriteSeedISO renders a cloud-init NoCloud seed ISO into dir and returns
+// its path. The seed configures GuestUser/GuestPassword for SSH login and
+// runs d's package manager to install packages on first boot.
+func WriteSeedISO(dir string, d Distro, packages []string) (string, error) {
+	return WriteSeedISOWithRunCmds(dir, d, packages, nil)
+}
+
+// WriteSeedISOWithRunCmds is like WriteSeedISO, but runs extraRunCmds
+// (each a single shell command) after the package install, e.g. to
+// configure and start a service the just-installed packages provide.
+func WriteSeedISOWithRunCmds(dir string, d Distro, packages []string, extraRunCmds []string) (string, error) {
+	runcmd, err := installCmd(d, packages)
+	if err != nil {
+		return "", err
+	}
+	runcmds := []string{runcmd}
+	runcmds = append(runcmds, extraRunCmds...)
+
+	userData := fmt.Sprintf(`#cloud-config
+hostname: %s
+users:
+  - default
+  - name: %s
+    lock_passwd: false
+    shell: /bin/sh
+ssh_pwauth: true
+chpasswd:
+  list: |
+    %s:%s
+  expire: false
+runcmd:
+%s
+`, d.Name, GuestUser, GuestUser, GuestPassword, runcmdLines(runcmds))
+
+	metaData := fmt.Sprintf("instance-id: %s\nlocal-hostname: %s\n", d.Name, d.Name)
+
+	seedDir, err := os.MkdirTemp(dir, "cloud-init-seed-")
+	if err != nil {
+		return "", fmt.Errorf("creating cloud-init seed dir: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(seedDir, "user-data"), []byte(userData), 0o644); err != nil {
+		return "", fmt.Errorf("writing user-data: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(seedDir, "meta-data"), []byte(metaData), 0o644); err != nil {
+		return "", fmt.Errorf("writing meta-data: %w", err)
+	}
+
+	iso := filepath.Join(dir, d.Name+"-seed.iso")
+	cmd := exec.Command("genisoimage", "-output", iso, "-volid", "cidata", "-joliet", "-rock",
+		filepath.Join(seedDir, "user-data"), filepath.Join(seedDir, "meta-data"))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("genisoimage: %v: %s", err, out)
+	}
+	return iso, nil
+}
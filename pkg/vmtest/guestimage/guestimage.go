@@ -0,0 +1,154 @@
+// Copyright 2024 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package guestimage lets integration tests boot a full Linux distro
+// from a qcow2 image as the non-SUT side of a test, alongside the usual
+// u-root-initramfs guest. It complements pkg/vmtest, which only knows
+// how to boot u-root initramfses.
+//
+// The usual sequence is LoadManifest, Fetch, WriteSeedISO, then Boot (or
+// BootAndDial, which also waits for sshd and connects).
+package guestimage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// Distro describes one entry of a distro manifest, e.g.
+// testdata/distros.json.
+type Distro struct {
+	// Name identifies the distro, e.g. "ubuntu-22.04".
+	Name string `json:"name"`
+
+	// URL is where the qcow2 image is downloaded from.
+	URL string `json:"url"`
+
+	// SHA256 is the expected hex-encoded SHA-256 checksum of the image.
+	// If SHA256 is empty, Fetch skips checksum validation entirely,
+	// logging loudly that it did so (see pkg/vmtest/kernels, which
+	// follows the same convention).
+	SHA256 string `json:"sha256"`
+
+	// Mem is the amount of memory, in MiB, the guest should be booted
+	// with.
+	Mem int `json:"mem"`
+
+	// PackageManager is the distro's package manager, e.g. "apt",
+	// "apk", "dnf", or "zypper". It selects which cloud-init
+	// runcmd is used to install packages on first boot.
+	PackageManager string `json:"packageManager"`
+}
+
+// LoadManifest reads a distro manifest such as testdata/distros.json.
+func LoadManifest(path string) ([]Distro, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading distro manifest: %w", err)
+	}
+	var distros []Distro
+	if err := json.Unmarshal(b, &distros); err != nil {
+		return nil, fmt.Errorf("parsing distro manifest %s: %w", path, err)
+	}
+	return distros, nil
+}
+
+// Find returns the Distro named name from distros, or an error if none
+// matches.
+func Find(distros []Distro, name string) (Distro, error) {
+	for _, d := range distros {
+		if d.Name == name {
+			return d, nil
+		}
+	}
+	return Distro{}, fmt.Errorf("no distro named %q in manifest", name)
+}
+
+// cacheDirEnv overrides the default guest image cache directory, mirroring
+// VMTEST_KERNEL_CACHE_DIR in pkg/vmtest/kernels.
+const cacheDirEnv = "VMTEST_GUESTIMAGE_CACHE_DIR"
+
+// Fetch downloads d's qcow2 image into the guest image cache (if not
+// already present and valid) and returns its local path. The downloaded
+// file's SHA-256 is validated against d.SHA256.
+func Fetch(d Distro) (string, error) {
+	dir := os.Getenv(cacheDirEnv)
+	if dir == "" {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			return "", fmt.Errorf("could not determine default guest image cache dir: %w", err)
+		}
+		dir = filepath.Join(base, "vmtest-guestimages")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("could not create guest image cache dir %q: %w", dir, err)
+	}
+
+	path := filepath.Join(dir, d.Name+".qcow2")
+	if err := verifySHA256(path, d.SHA256); err == nil {
+		return path, nil
+	}
+
+	tmp := path + ".tmp"
+	if err := downloadFile(d.URL, tmp); err != nil {
+		return "", fmt.Errorf("fetching guest image %s: %w", d.Name, err)
+	}
+	if err := verifySHA256(tmp, d.SHA256); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("fetched guest image %s failed checksum validation: %w", d.Name, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return "", fmt.Errorf("caching guest image %s: %w", d.Name, err)
+	}
+	return path, nil
+}
+
+func verifySHA256(path, want string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if want == "" {
+		log.Printf("guestimage: %s has no SHA256 pinned, skipping checksum validation", path)
+		return nil
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != want {
+		return fmt.Errorf("checksum mismatch for %q: got %s, want %s", path, got, want)
+	}
+	return nil
+}
+
+func downloadFile(url, dest string) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, resp.Body)
+	return err
+}
@@ -0,0 +1,36 @@
+// Copyright 2024 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guestimage
+
+import "testing"
+
+func TestLoadManifestAndFind(t *testing.T) {
+	distros, err := LoadManifest("../../../integration/testdata/distros.json")
+	if err != nil {
+		t.Fatalf("LoadManifest: %v", err)
+	}
+	if len(distros) == 0 {
+		t.Fatal("LoadManifest returned no distros")
+	}
+
+	d, err := Find(distros, "alpine-3.19")
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if d.PackageManager != "apk" {
+		t.Errorf("PackageManager = %q, want apk", d.PackageManager)
+	}
+
+	if _, err := Find(distros, "does-not-exist"); err == nil {
+		t.Error("Find succeeded for a distro not in the manifest")
+	}
+}
+
+func TestInstallCmdUnknownPackageManager(t *testing.T) {
+	d := Distro{Name: "mystery", PackageManager: "pacman"}
+	if _, err := installCmd(d, []string{"iproute2"}); err == nil {
+		t.Error("installCmd succeeded for an unsupported package manager")
+	}
+}
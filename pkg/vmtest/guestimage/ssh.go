@@ -0,0 +1,86 @@
+// Copyright 2024 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guestimage
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// VM is a running guest booted from a Distro image, reachable over SSH at
+// Addr (typically a host-forwarded "127.0.0.1:PORT").
+type VM struct {
+	Addr   string
+	client *ssh.Client
+}
+
+// DialSSH connects to a guest VM over SSH, retrying until timeout expires
+// to allow for the time it takes sshd to come up after first boot.
+func DialSSH(addr string, timeout time.Duration) (*VM, error) {
+	cfg := &ssh.ClientConfig{
+		User:            GuestUser,
+		Auth:            []ssh.AuthMethod{ssh.Password(GuestPassword)},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Timeout:         5 * time.Second,
+	}
+
+	deadline := time.Now().Add(timeout)
+	var lastErr error
+	for time.Now().Before(deadline) {
+		client, err := ssh.Dial("tcp", addr, cfg)
+		if err == nil {
+			return &VM{Addr: addr, client: client}, nil
+		}
+		lastErr = err
+		time.Sleep(time.Second)
+	}
+	return nil, fmt.Errorf("dialing guest SSH at %s: %w", addr, lastErr)
+}
+
+// Close closes the underlying SSH connection.
+func (vm *VM) Close() error {
+	return vm.client.Close()
+}
+
+// SSHRun runs cmd on the guest and returns its combined stdout+stderr.
+func (vm *VM) SSHRun(cmd string) (string, error) {
+	session, err := vm.client.NewSession()
+	if err != nil {
+		return "", fmt.Errorf("opening SSH session: %w", err)
+	}
+	defer session.Close()
+
+	var out bytes.Buffer
+	session.Stdout = &out
+	session.Stderr = &out
+	if err := session.Run(cmd); err != nil {
+		return out.String(), fmt.Errorf("running %q: %w", cmd, err)
+	}
+	return out.String(), nil
+}
+
+// SSHPut writes data to path on the guest over SFTP.
+func (vm *VM) SSHPut(path string, data []byte) error {
+	sc, err := sftp.NewClient(vm.client)
+	if err != nil {
+		return fmt.Errorf("opening SFTP client: %w", err)
+	}
+	defer sc.Close()
+
+	f, err := sc.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating %s on guest: %w", path, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("writing %s on guest: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2024 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package kernels provides a small registry of downloadable kernel images
+// used by integration tests that need to exercise more than one kernel
+// version, plus a cache-aware fetcher for them.
+package kernels
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// KernelSpec describes a single downloadable kernel image used by
+// integration tests.
+type KernelSpec struct {
+	// Name identifies the kernel, e.g. "5.10", and is used both as the
+	// subtest name and, after Arch, in the cache file name ("amd64-5.10").
+	Name string
+
+	// URL is the location the kernel image is fetched from.
+	URL string
+
+	// SHA256 is the expected hex-encoded SHA-256 checksum of the
+	// downloaded image. Fetch refuses to return a cached or freshly
+	// downloaded file whose checksum does not match. If SHA256 is
+	// empty, Fetch skips checksum validation entirely.
+	SHA256 string
+
+	// Arch is the GOARCH this kernel was built for, e.g. "amd64" or
+	// "arm64".
+	Arch string
+}
+
+// cacheDirEnv names the environment variable that overrides the default
+// kernel cache directory.
+const cacheDirEnv = "VMTEST_KERNEL_CACHE_DIR"
+
+// CacheDir returns the directory kernel images are cached in. It honors
+// VMTEST_KERNEL_CACHE_DIR and otherwise falls back to a "vmtest-kernels"
+// directory under os.UserCacheDir.
+func CacheDir() (string, error) {
+	if dir := os.Getenv(cacheDirEnv); dir != "" {
+		return dir, nil
+	}
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("could not determine default kernel cache dir: %w", err)
+	}
+	return filepath.Join(base, "vmtest-kernels"), nil
+}
+
+// Fetch downloads spec into the kernel cache (if not already present and
+// valid) and returns the local path to it. Fetch validates the SHA-256 of
+// the cached or downloaded file against spec.SHA256 and re-downloads if a
+// cached file fails validation.
+func Fetch(spec KernelSpec) (string, error) {
+	dir, err := CacheDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("could not create kernel cache dir %q: %w", dir, err)
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s", spec.Arch, spec.Name))
+	if err := verify(path, spec.SHA256); err == nil {
+		return path, nil
+	}
+
+	tmp := path + ".tmp"
+	if err := download(spec.URL, tmp); err != nil {
+		return "", fmt.Errorf("fetching kernel %s: %w", spec.Name, err)
+	}
+	if err := verify(tmp, spec.SHA256); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("fetched kernel %s failed checksum validation: %w", spec.Name, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return "", fmt.Errorf("caching kernel %s: %w", spec.Name, err)
+	}
+	return path, nil
+}
+
+// verify returns nil if the file at path exists and its SHA-256 checksum
+// matches want. An empty want skips checksum validation, only checking
+// that path exists.
+func verify(path, want string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if want == "" {
+		log.Printf("kernels: %s has no SHA256 pinned, skipping checksum validation", path)
+		return nil
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	got := hex.EncodeToString(h.Sum(nil))
+	if got != want {
+		return fmt.Errorf("checksum mismatch for %q: got %s, want %s", path, got, want)
+	}
+	return nil
+}
+
+func download(url, dest string) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, resp.Body)
+	return err
+}
@@ -0,0 +1,48 @@
+// Copyright 2024 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kernels
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "kernel")
+	if err := os.WriteFile(path, []byte("hello"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	const wrongSHA256 = "0000000000000000000000000000000000000000000000000000000000000000"
+	if err := verify(path, wrongSHA256); err == nil {
+		t.Error("verify succeeded with a deliberately wrong checksum")
+	}
+
+	// sha256("hello")
+	const helloSHA256 = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	if err := verify(path, helloSHA256); err != nil {
+		t.Errorf("verify with correct checksum failed: %v", err)
+	}
+
+	if err := verify(path, ""); err != nil {
+		t.Errorf("verify with empty want should skip validation, got: %v", err)
+	}
+	if err := verify(filepath.Join(dir, "does-not-exist"), ""); err == nil {
+		t.Error("verify succeeded for a nonexistent file even with an empty want")
+	}
+}
+
+func TestCacheDirHonorsEnv(t *testing.T) {
+	t.Setenv(cacheDirEnv, "/tmp/custom-kernel-cache")
+	dir, err := CacheDir()
+	if err != nil {
+		t.Fatalf("CacheDir: %v", err)
+	}
+	if dir != "/tmp/custom-kernel-cache" {
+		t.Errorf("CacheDir() = %q, want %q", dir, "/tmp/custom-kernel-cache")
+	}
+}
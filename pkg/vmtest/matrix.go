@@ -0,0 +1,38 @@
+// Copyright 2024 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vmtest
+
+import (
+	"testing"
+
+	"github.com/u-root/u-root/pkg/vmtest/kernels"
+)
+
+// RunAcrossKernels runs fn once per kernel in specs, each as its own
+// subtest named after spec.Name, so that `go test -run` can select a
+// single kernel version and test runners can report per-kernel
+// pass/fail.
+//
+// Kernels that do not match the current GOARCH (spec.Arch) are skipped.
+// fn is called with the local filesystem path to the fetched kernel
+// image.
+func RunAcrossKernels(t *testing.T, specs []kernels.KernelSpec, fn func(t *testing.T, kernelPath string)) {
+	t.Helper()
+
+	for _, spec := range specs {
+		spec := spec
+		t.Run(spec.Name, func(t *testing.T) {
+			if spec.Arch != TestArch() {
+				t.Skipf("kernel %s is built for %s, not %s", spec.Name, spec.Arch, TestArch())
+			}
+
+			path, err := kernels.Fetch(spec)
+			if err != nil {
+				t.Skipf("could not fetch kernel %s: %v", spec.Name, err)
+			}
+			fn(t, path)
+		})
+	}
+}
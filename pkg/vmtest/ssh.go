@@ -0,0 +1,138 @@
+// Copyright 2024 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vmtest
+
+import (
+	"fmt"
+	"time"
+
+	expect "github.com/google/goexpect"
+	"golang.org/x/crypto/ssh"
+)
+
+// SSHOptions configures the SSH transport set up by WithSSH.
+type SSHOptions struct {
+	// User is the guest user to authenticate as.
+	User string
+
+	// KeyPath is the path to a private key file authorized for User in
+	// the guest.
+	KeyPath string
+}
+
+// WithSSH configures a guest's SSH transport, authenticating as user
+// with the private key at keyPath. Use it together with DialGuestSSH
+// once the guest's sshd port has been forwarded to the host.
+func WithSSH(user, keyPath string) SSHOptions {
+	return SSHOptions{User: user, KeyPath: keyPath}
+}
+
+// GuestSSH is an SSH connection to a guest VM, exposing both direct
+// command execution and a goexpect.GExpect backed by a PTY session. It's
+// an alternative to driving a test purely through the QEMU serial
+// console, which mixes kernel log output with test output.
+type GuestSSH struct {
+	client *ssh.Client
+}
+
+// DialGuestSSH connects to a guest's sshd at hostAddr (e.g.
+// "127.0.0.1:2222", typically a QEMU hostfwd target), retrying until
+// timeout elapses to allow for the time it takes sshd to come up.
+func DialGuestSSH(hostAddr string, opts SSHOptions, timeout time.Duration) (*GuestSSH, error) {
+	signer, err := loadPrivateKey(opts.KeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("loading SSH key %s: %w", opts.KeyPath, err)
+	}
+
+	cfg := &ssh.ClientConfig{
+		User:            opts.User,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Timeout:         5 * time.Second,
+	}
+
+	deadline := time.Now().Add(timeout)
+	var lastErr error
+	for time.Now().Before(deadline) {
+		client, err := ssh.Dial("tcp", hostAddr, cfg)
+		if err == nil {
+			return &GuestSSH{client: client}, nil
+		}
+		lastErr = err
+		time.Sleep(time.Second)
+	}
+	return nil, fmt.Errorf("dialing guest sshd at %s: %w", hostAddr, lastErr)
+}
+
+// Client returns the underlying *ssh.Client, e.g. to open an sftp client
+// for file transfers.
+func (g *GuestSSH) Client() *ssh.Client {
+	return g.client
+}
+
+// Close closes the SSH connection.
+func (g *GuestSSH) Close() error {
+	return g.client.Close()
+}
+
+// Run runs cmd on the guest and returns its combined stdout+stderr.
+func (g *GuestSSH) Run(cmd string) (string, error) {
+	session, err := g.client.NewSession()
+	if err != nil {
+		return "", fmt.Errorf("opening SSH session: %w", err)
+	}
+	defer session.Close()
+
+	out, err := session.CombinedOutput(cmd)
+	if err != nil {
+		return string(out), fmt.Errorf("running %q over SSH: %w", cmd, err)
+	}
+	return string(out), nil
+}
+
+// Expect opens a PTY-backed SSH session running an interactive shell and
+// returns a goexpect.GExpect over it, so existing Expect-based test
+// assertions can be reused with structured, un-mixed-with-kernel-log
+// output.
+func (g *GuestSSH) Expect(timeout time.Duration) (*expect.GExpect, <-chan error, error) {
+	session, err := g.client.NewSession()
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening SSH session: %w", err)
+	}
+	if err := session.RequestPty("xterm", 80, 40, ssh.TerminalModes{}); err != nil {
+		session.Close()
+		return nil, nil, fmt.Errorf("requesting PTY: %w", err)
+	}
+
+	in, err := session.StdinPipe()
+	if err != nil {
+		session.Close()
+		return nil, nil, fmt.Errorf("opening stdin pipe: %w", err)
+	}
+	out, err := session.StdoutPipe()
+	if err != nil {
+		session.Close()
+		return nil, nil, fmt.Errorf("opening stdout pipe: %w", err)
+	}
+	if err := session.Shell(); err != nil {
+		session.Close()
+		return nil, nil, fmt.Errorf("starting shell: %w", err)
+	}
+
+	e, _, err := expect.SpawnGeneric(&expect.GenOptions{
+		In:  in,
+		Out: out,
+		Wait: func() error {
+			return session.Wait()
+		},
+		Close: session.Close,
+		Check: func() bool { return true },
+	}, timeout)
+	if err != nil {
+		session.Close()
+		return nil, nil, fmt.Errorf("spawning expecter over SSH session: %w", err)
+	}
+	return e, nil, nil
+}
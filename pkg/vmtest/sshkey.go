@@ -0,0 +1,21 @@
+// Copyright 2024 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vmtest
+
+import (
+	"os"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// loadPrivateKey parses an unencrypted private key file for use with
+// ssh.PublicKeys.
+func loadPrivateKey(path string) (ssh.Signer, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ssh.ParsePrivateKey(b)
+}